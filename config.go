@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	sdk_metric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// exporterKind selects which metric exporter buildMeterProvider wires up.
+type exporterKind string
+
+const (
+	exporterOTLPHTTP   exporterKind = "otlphttp"
+	exporterOTLPGRPC   exporterKind = "otlpgrpc"
+	exporterPrometheus exporterKind = "prometheus"
+	exporterStdout     exporterKind = "stdout"
+)
+
+// config holds everything buildMeterProvider needs to assemble a MeterProvider, gathered
+// from the standard OTel environment variables and the -exporter flag.
+type config struct {
+	exporter exporterKind
+
+	// otlpProtocol records OTEL_EXPORTER_OTLP_PROTOCOL. parseConfig uses it only to pick
+	// the default -exporter; an explicit -exporter flag takes precedence over it.
+	otlpProtocol string
+	otlpEndpoint string
+	otlpHeaders  map[string]string
+
+	exportInterval      time.Duration
+	exemplarFilter      exemplar.Filter
+	temporalitySelector sdk_metric.TemporalitySelector
+	prometheusAddr      string
+}
+
+// parseConfig builds a config from the standard OTel env vars plus the -exporter and
+// -prometheus-addr CLI flags. It calls flag.Parse(); it must not be called more than once.
+func parseConfig() (config, error) {
+	otlpProtocol := envOr("OTEL_EXPORTER_OTLP_PROTOCOL", "http/protobuf")
+
+	// When -exporter isn't given explicitly, default it from OTEL_EXPORTER_OTLP_PROTOCOL
+	// so that setting just the standard env var is enough to switch transports.
+	defaultExporter := exporterOTLPHTTP
+	if otlpProtocol == "grpc" {
+		defaultExporter = exporterOTLPGRPC
+	}
+
+	exporterFlag := flag.String("exporter", string(defaultExporter), "metric exporter to use: otlphttp, otlpgrpc, prometheus, or stdout")
+	prometheusAddr := flag.String("prometheus-addr", ":9464", "address to serve Prometheus /metrics on, when -exporter=prometheus")
+	flag.Parse()
+
+	cfg := config{
+		exporter:            exporterKind(*exporterFlag),
+		otlpProtocol:        otlpProtocol,
+		otlpEndpoint:        os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"),
+		otlpHeaders:         parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_METRICS_HEADERS")),
+		exportInterval:      5 * time.Second,
+		exemplarFilter:      exemplar.TraceBasedFilter,
+		temporalitySelector: buildTemporalitySelector(temporalityCumulative),
+		prometheusAddr:      *prometheusAddr,
+	}
+
+	switch cfg.exporter {
+	case exporterOTLPHTTP, exporterOTLPGRPC, exporterPrometheus, exporterStdout:
+	default:
+		return config{}, fmt.Errorf("unknown -exporter %q: must be one of otlphttp, otlpgrpc, prometheus, stdout", cfg.exporter)
+	}
+
+	if raw := os.Getenv("OTEL_METRIC_EXPORT_INTERVAL"); raw != "" {
+		ms, err := strconv.Atoi(raw)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid OTEL_METRIC_EXPORT_INTERVAL %q: %w", raw, err)
+		}
+		cfg.exportInterval = time.Duration(ms) * time.Millisecond
+	}
+
+	if raw := os.Getenv("OTEL_METRICS_EXEMPLAR_FILTER"); raw != "" {
+		switch raw {
+		case "trace_based":
+			cfg.exemplarFilter = exemplar.TraceBasedFilter
+		case "always_on":
+			cfg.exemplarFilter = exemplar.AlwaysOnFilter
+		case "always_off":
+			cfg.exemplarFilter = exemplar.AlwaysOffFilter
+		default:
+			return config{}, fmt.Errorf("unknown OTEL_METRICS_EXEMPLAR_FILTER %q: must be one of trace_based, always_on, always_off", raw)
+		}
+	}
+
+	// Prometheus's OTLP receiver only accumulates cumulative temporality out of the box,
+	// so that's the default (set above); OTEL_METRICS_TEMPORALITY=delta opts into delta
+	// for counters and histograms, for backends that prefer it.
+	if raw := os.Getenv("OTEL_METRICS_TEMPORALITY"); raw != "" {
+		switch temporalityMode(raw) {
+		case temporalityCumulative, temporalityDelta:
+			cfg.temporalitySelector = buildTemporalitySelector(temporalityMode(raw))
+		default:
+			return config{}, fmt.Errorf("unknown OTEL_METRICS_TEMPORALITY %q: must be one of cumulative, delta", raw)
+		}
+	}
+
+	return cfg, nil
+}
+
+// envOr returns the value of the given environment variable, or fallback if it is unset.
+func envOr(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
+
+// parseHeaders parses a comma-separated list of key=value pairs, the format used by
+// OTEL_EXPORTER_OTLP_METRICS_HEADERS.
+func parseHeaders(raw string) map[string]string {
+	if raw == "" {
+		return nil
+	}
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers
+}
+
+// buildMeterProvider assembles a MeterProvider per cfg. For the prometheus exporter it
+// also starts the /metrics HTTP server in the background; callers don't need to do
+// anything further to make scraping work.
+//
+// The exemplar filter defaults to "trace-based" (see cfg.exemplarFilter), so a measurement
+// recorded while a sampled span is active in its context is attached to the exported data
+// point as an exemplar carrying that span's trace/span IDs. Prometheus's OTLP receiver
+// (exposed at /api/v1/otlp/v1/metrics) stores these as native exemplars, letting you jump
+// from a bucket in a Prometheus histogram straight to the trace that produced it.
+func buildMeterProvider(ctx context.Context, res *resource.Resource, cfg config) (*sdk_metric.MeterProvider, error) {
+	opts := []sdk_metric.Option{
+		sdk_metric.WithResource(res),
+		// Attach trace/span IDs as exemplars to exported data points whenever the
+		// measurement happens inside a sampled span.
+		sdk_metric.WithExemplarFilter(cfg.exemplarFilter),
+		// Swap aggregations per instrument, e.g. the exponential histogram on
+		// demo.request.size, so the example can show both histogram flavors.
+		sdk_metric.WithView(buildViews(defaultViewRules)...),
+	}
+
+	switch cfg.exporter {
+	case exporterOTLPHTTP:
+		exp, err := newOTLPHTTPExporter(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP HTTP metric exporter: %w", err)
+		}
+		opts = append(opts, sdk_metric.WithReader(sdk_metric.NewPeriodicReader(exp, sdk_metric.WithInterval(cfg.exportInterval))))
+
+	case exporterOTLPGRPC:
+		exp, err := newOTLPGRPCExporter(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC metric exporter: %w", err)
+		}
+		opts = append(opts, sdk_metric.WithReader(sdk_metric.NewPeriodicReader(exp, sdk_metric.WithInterval(cfg.exportInterval))))
+
+	case exporterPrometheus:
+		exp, err := prometheus.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+		}
+		opts = append(opts, sdk_metric.WithReader(exp))
+		go serveMetrics(cfg.prometheusAddr)
+
+	case exporterStdout:
+		exp, err := stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout metric exporter: %w", err)
+		}
+		opts = append(opts, sdk_metric.WithReader(sdk_metric.NewPeriodicReader(exp, sdk_metric.WithInterval(cfg.exportInterval))))
+
+	default:
+		return nil, fmt.Errorf("unknown exporter %q", cfg.exporter)
+	}
+
+	return sdk_metric.NewMeterProvider(opts...), nil
+}
+
+func newOTLPHTTPExporter(ctx context.Context, cfg config) (sdk_metric.Exporter, error) {
+	httpOpts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithHeaders(cfg.otlpHeaders),
+		otlpmetrichttp.WithTemporalitySelector(cfg.temporalitySelector),
+	}
+	endpoint := cfg.otlpEndpoint
+	if endpoint == "" {
+		endpoint = "http://localhost:9090/api/v1/otlp/v1/metrics"
+	}
+	httpOpts = append(httpOpts, otlpmetrichttp.WithEndpointURL(endpoint))
+	return otlpmetrichttp.New(ctx, httpOpts...)
+}
+
+func newOTLPGRPCExporter(ctx context.Context, cfg config) (sdk_metric.Exporter, error) {
+	grpcOpts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithHeaders(cfg.otlpHeaders),
+		otlpmetricgrpc.WithTemporalitySelector(cfg.temporalitySelector),
+	}
+	endpoint := cfg.otlpEndpoint
+	if endpoint == "" {
+		endpoint = "http://localhost:4317"
+	}
+	grpcOpts = append(grpcOpts, otlpmetricgrpc.WithEndpointURL(endpoint))
+	return otlpmetricgrpc.New(ctx, grpcOpts...)
+}
+
+// serveMetrics starts the Prometheus scrape endpoint and logs (rather than panics) if it
+// ever exits, since it runs detached from the main goroutine.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	if err := http.ListenAndServe(addr, mux); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintf(os.Stderr, "Prometheus metrics server exited: %v\n", err)
+	}
+}
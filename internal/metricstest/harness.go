@@ -0,0 +1,163 @@
+// Package metricstest provides a small in-process harness for asserting on the metrics a
+// MeterProvider has recorded, without needing a live collector or Prometheus server.
+package metricstest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"go.opentelemetry.io/otel/attribute"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdk_metric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Harness is a MeterProvider wired up for tests: a ManualReader for exact, typed
+// assertions via MustGetCounter/MustGetHistogramSnapshot, and a Prometheus exporter
+// served over httptest for tests that want to assert on the rendered exposition format
+// via Gather.
+type Harness struct {
+	t *testing.T
+
+	reader     *sdk_metric.ManualReader
+	promServer *httptest.Server
+	mp         *sdk_metric.MeterProvider
+}
+
+// New starts a Harness. Call t.Cleanup is not required: the underlying httptest server is
+// closed automatically via t.Cleanup.
+func New(t *testing.T) *Harness {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	promExporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		t.Fatalf("failed to create Prometheus exporter: %v", err)
+	}
+
+	reader := sdk_metric.NewManualReader()
+	mp := sdk_metric.NewMeterProvider(
+		sdk_metric.WithReader(reader),
+		sdk_metric.WithReader(promExporter),
+	)
+
+	server := httptest.NewServer(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	t.Cleanup(server.Close)
+
+	return &Harness{t: t, reader: reader, promServer: server, mp: mp}
+}
+
+// Meter returns a Meter backed by this Harness's MeterProvider.
+func (h *Harness) Meter(name string) metric.Meter {
+	return h.mp.Meter(name)
+}
+
+// Gather scrapes the Prometheus exposition endpoint and parses it into metric families,
+// the same representation a real Prometheus server would see.
+func (h *Harness) Gather() []*dto.MetricFamily {
+	h.t.Helper()
+
+	resp, err := http.Get(h.promServer.URL + "/metrics")
+	if err != nil {
+		h.t.Fatalf("failed to scrape metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	families, err := (&expfmt.TextParser{}).TextToMetricFamilies(resp.Body)
+	if err != nil {
+		h.t.Fatalf("failed to parse scraped metrics: %v", err)
+	}
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		result = append(result, family)
+	}
+	return result
+}
+
+// MustGetCounter returns the current value of the int64 counter or up-down-counter named
+// name with the given attributes, failing the test if it isn't found.
+func (h *Harness) MustGetCounter(name string, attrs ...attribute.KeyValue) int64 {
+	h.t.Helper()
+
+	sum, ok := h.mustGetMetric(name).Data.(metricdata.Sum[int64])
+	if !ok {
+		h.t.Fatalf("metric %q is not an int64 Sum", name)
+	}
+	for _, dp := range sum.DataPoints {
+		if attributeSetMatches(dp.Attributes, attrs) {
+			return dp.Value
+		}
+	}
+	h.t.Fatalf("metric %q has no data point matching attributes %v", name, attrs)
+	return 0
+}
+
+// HistogramSnapshot is a point-in-time view of one data point of a float64 histogram.
+type HistogramSnapshot struct {
+	Count        uint64
+	Sum          float64
+	Bounds       []float64
+	BucketCounts []uint64
+}
+
+// MustGetHistogramSnapshot returns the current state of the float64 histogram named name
+// with the given attributes, failing the test if it isn't found.
+func (h *Harness) MustGetHistogramSnapshot(name string, attrs ...attribute.KeyValue) HistogramSnapshot {
+	h.t.Helper()
+
+	hist, ok := h.mustGetMetric(name).Data.(metricdata.Histogram[float64])
+	if !ok {
+		h.t.Fatalf("metric %q is not a float64 Histogram", name)
+	}
+	for _, dp := range hist.DataPoints {
+		if attributeSetMatches(dp.Attributes, attrs) {
+			return HistogramSnapshot{
+				Count:        dp.Count,
+				Sum:          dp.Sum,
+				Bounds:       dp.Bounds,
+				BucketCounts: dp.BucketCounts,
+			}
+		}
+	}
+	h.t.Fatalf("metric %q has no data point matching attributes %v", name, attrs)
+	return HistogramSnapshot{}
+}
+
+func (h *Harness) mustGetMetric(name string) metricdata.Metrics {
+	h.t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := h.reader.Collect(context.Background(), &rm); err != nil {
+		h.t.Fatalf("failed to collect metrics: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+	h.t.Fatalf("metric %q was not recorded", name)
+	return metricdata.Metrics{}
+}
+
+func attributeSetMatches(set attribute.Set, want []attribute.KeyValue) bool {
+	if set.Len() != len(want) {
+		return false
+	}
+	for _, kv := range want {
+		v, ok := set.Value(kv.Key)
+		if !ok || v != kv.Value {
+			return false
+		}
+	}
+	return true
+}
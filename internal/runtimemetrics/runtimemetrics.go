@@ -0,0 +1,144 @@
+// Package runtimemetrics registers a set of asynchronous OpenTelemetry instruments that
+// report baseline Go runtime and process observability (heap usage, GC activity,
+// goroutine/cgo counts, CPU time, and RSS) so example services get this out of the box
+// alongside their hand-recorded metrics.
+package runtimemetrics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Start registers the runtime/process instruments on meter. It returns an error if any
+// instrument fails to register.
+func Start(meter metric.Meter) error {
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return fmt.Errorf("failed to look up current process: %w", err)
+	}
+
+	heapAlloc, err := meter.Int64ObservableGauge(
+		"process.runtime.go.mem.heap_alloc",
+		metric.WithDescription("Bytes of allocated heap objects."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.runtime.go.mem.heap_alloc: %w", err)
+	}
+	heapInuse, err := meter.Int64ObservableGauge(
+		"process.runtime.go.mem.heap_inuse",
+		metric.WithDescription("Bytes in in-use spans."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.runtime.go.mem.heap_inuse: %w", err)
+	}
+	heapObjects, err := meter.Int64ObservableGauge(
+		"process.runtime.go.mem.heap_objects",
+		metric.WithDescription("Number of allocated heap objects."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.runtime.go.mem.heap_objects: %w", err)
+	}
+	gcCount, err := meter.Int64ObservableCounter(
+		"process.runtime.go.gc.count",
+		metric.WithDescription("Number of completed garbage collection cycles."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.runtime.go.gc.count: %w", err)
+	}
+	goroutines, err := meter.Int64ObservableGauge(
+		"process.runtime.go.goroutines",
+		metric.WithDescription("Number of goroutines that currently exist."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.runtime.go.goroutines: %w", err)
+	}
+	cgoCalls, err := meter.Int64ObservableCounter(
+		"process.runtime.go.cgo.calls",
+		metric.WithDescription("Number of cgo calls made by the process."),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.runtime.go.cgo.calls: %w", err)
+	}
+	cpuTime, err := meter.Float64ObservableCounter(
+		"process.cpu.time",
+		metric.WithDescription("Total CPU seconds consumed by the process."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.cpu.time: %w", err)
+	}
+	rss, err := meter.Int64ObservableGauge(
+		"process.memory.rss",
+		metric.WithDescription("Resident set size of the process."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.memory.rss: %w", err)
+	}
+
+	// GC pause times are reported as a histogram, fed from the ring buffer of recent
+	// pause durations in runtime.MemStats rather than as an asynchronous instrument,
+	// since a histogram aggregates individual observations rather than a single value.
+	gcPause, err := meter.Float64Histogram(
+		"process.runtime.go.gc.pause",
+		metric.WithDescription("Amount of time spent in garbage collection stop-the-world pauses."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create process.runtime.go.gc.pause: %w", err)
+	}
+
+	var lastNumGC uint32
+	_, err = meter.RegisterCallback(
+		func(ctx context.Context, o metric.Observer) error {
+			var memStats runtime.MemStats
+			runtime.ReadMemStats(&memStats)
+
+			o.ObserveInt64(heapAlloc, int64(memStats.HeapAlloc))
+			o.ObserveInt64(heapInuse, int64(memStats.HeapInuse))
+			o.ObserveInt64(heapObjects, int64(memStats.HeapObjects))
+			o.ObserveInt64(gcCount, int64(memStats.NumGC))
+			o.ObserveInt64(goroutines, int64(runtime.NumGoroutine()))
+			o.ObserveInt64(cgoCalls, runtime.NumCgoCall())
+
+			// runtime.MemStats.PauseNs is a ring buffer of the most recent 256 pause
+			// durations; replay only the ones recorded since the last callback.
+			numNewPauses := memStats.NumGC - lastNumGC
+			if numNewPauses > uint32(len(memStats.PauseNs)) {
+				numNewPauses = uint32(len(memStats.PauseNs))
+			}
+			for i := uint32(0); i < numNewPauses; i++ {
+				idx := (memStats.NumGC - 1 - i) % uint32(len(memStats.PauseNs))
+				gcPause.Record(ctx, float64(memStats.PauseNs[idx])/1e9)
+			}
+			lastNumGC = memStats.NumGC
+
+			times, err := proc.Times()
+			if err != nil {
+				return fmt.Errorf("failed to read process CPU times: %w", err)
+			}
+			o.ObserveFloat64(cpuTime, times.User+times.System)
+
+			memInfo, err := proc.MemoryInfo()
+			if err != nil {
+				return fmt.Errorf("failed to read process memory info: %w", err)
+			}
+			o.ObserveInt64(rss, int64(memInfo.RSS))
+
+			return nil
+		},
+		heapAlloc, heapInuse, heapObjects, gcCount, goroutines, cgoCalls, cpuTime, rss,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register runtime metrics callback: %w", err)
+	}
+
+	return nil
+}
@@ -9,28 +9,23 @@ import (
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
-	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/metric"
-	sdk_metric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/promlabs/otel-instrumentation-example/internal/runtimemetrics"
 )
 
 func main() {
 	// Handle SIGINT (CTRL+C) gracefully.
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 
-	// Create an OTLP metric exporter that sends all metrics to the local Prometheus server.
-	otlpMetricExporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL("http://localhost:9090/api/v1/otlp/v1/metrics"))
-	if err != nil {
-		log.Fatalf("Failed to create OTLP metric exporter: %v", err)
-	}
-
-	// OPTIONAL: Create a stdout exporter that periodically logs the metrics to stdout.
-	stdoutMetricExporter, err := stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+	cfg, err := parseConfig()
 	if err != nil {
-		log.Fatalf("Failed to create stdout metric exporter: %v", err)
+		log.Fatalf("Failed to parse configuration: %v", err)
 	}
 
 	res, err := resource.Merge(
@@ -45,35 +40,65 @@ func main() {
 		log.Fatalf("failed to create resource: %v", err)
 	}
 
-	// Create a new MeterProvider with a reader that sends metrics to the OTLP exporter every 5 seconds.
-	meterProvider := sdk_metric.NewMeterProvider(
-		sdk_metric.WithResource(res),
-		// Send metrics via OTLP.
-		sdk_metric.WithReader(sdk_metric.NewPeriodicReader(otlpMetricExporter, sdk_metric.WithInterval(5*time.Second))),
-		// OPTIONAL: Log metrics to stdout.
-		sdk_metric.WithReader(sdk_metric.NewPeriodicReader(stdoutMetricExporter, sdk_metric.WithInterval(5*time.Second))),
-	)
-	// Ensure the MeterProvider is flushed and shut down properly when terminating the program.
-	defer meterProvider.Shutdown(context.Background())
+	// Both providers share the same Resource so traces and metrics are attributed to the
+	// same "job"/"instance" in the backend.
+	meterProvider, err := buildMeterProvider(ctx, res, cfg)
+	if err != nil {
+		log.Fatalf("Failed to build MeterProvider: %v", err)
+	}
+	tracerProvider := setupTracerProvider(ctx, res)
 
-	// Set the global MeterProvider to the newly created MeterProvider.
-	// This enables calls like otel.Meter() anywhere in the application rather than having to pass the MeterProvider around.
+	// Set the global providers.
+	// This enables calls like otel.Meter()/otel.Tracer() anywhere in the application
+	// rather than having to pass the providers around.
 	otel.SetMeterProvider(meterProvider)
+	otel.SetTracerProvider(tracerProvider)
 
-	// Create a new Meter.
+	// Create a new Meter and Tracer.
 	meter := otel.Meter("otel-instrumentation-example")
+	tracer := otel.Tracer("otel-instrumentation-example")
 
-	// Create and record some example metrics.
-	createAndRecordMetrics(ctx, meter)
+	// Report baseline Go runtime and process metrics alongside the hand-recorded ones below.
+	if err := runtimemetrics.Start(meter); err != nil {
+		log.Fatalf("Failed to start runtime metrics: %v", err)
+	}
+
+	// Create and record some example metrics, with the histogram recorded inside a span
+	// so that sampled observations pick up a trace-based exemplar.
+	createAndRecordMetrics(ctx, meter, tracer)
 
 	// Wait for interruption / first CTRL+C.
 	<-ctx.Done()
 	log.Println("Shutting down...")
 	// Stop receiving further signal notifications as soon as possible.
 	stop()
+
+	// Shut down the TracerProvider before the MeterProvider so that any spans still in
+	// flight are flushed first; that way the final metrics export can carry exemplars
+	// for traces the collector has already received.
+	if err := tracerProvider.Shutdown(context.Background()); err != nil {
+		log.Printf("Failed to shut down TracerProvider: %v", err)
+	}
+	if err := meterProvider.Shutdown(context.Background()); err != nil {
+		log.Printf("Failed to shut down MeterProvider: %v", err)
+	}
 }
 
-func createAndRecordMetrics(ctx context.Context, meter metric.Meter) {
+// setupTracerProvider builds the TracerProvider used for all spans created by this example,
+// exporting to a local collector alongside the metrics so the two can be correlated.
+func setupTracerProvider(ctx context.Context, res *resource.Resource) *sdktrace.TracerProvider {
+	otlpTraceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL("http://localhost:4318/v1/traces"))
+	if err != nil {
+		log.Fatalf("Failed to create OTLP trace exporter: %v", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(otlpTraceExporter),
+	)
+}
+
+func createAndRecordMetrics(ctx context.Context, meter metric.Meter, tracer trace.Tracer) {
 	// Counter.
 	counter, err := meter.Int64Counter("demo.handled_items")
 	if err != nil {
@@ -97,7 +122,8 @@ func createAndRecordMetrics(ctx context.Context, meter metric.Meter) {
 	}
 	gauge.Record(ctx, time.Now().Unix()) // Set to the current Unix timestamp in seconds.
 
-	// Histogram.
+	// Histogram, recorded inside a span so sampled requests get an exemplar linking the
+	// bucket back to this trace.
 	histogram, err := meter.Float64Histogram(
 		"demo.request.duration",
 		metric.WithDescription("The distribution of demo request durations."),
@@ -107,10 +133,27 @@ func createAndRecordMetrics(ctx context.Context, meter metric.Meter) {
 	if err != nil {
 		log.Fatalf("Failed to create Histogram: %v", err)
 	}
-	histogram.Record(ctx, 0.023) // Record a request that took 0.023 seconds.
-	histogram.Record(ctx, 1.632) // Record a request that took 1.632 seconds.
-	histogram.Record(ctx, 0.345) // Record a request that took 0.345 seconds.
-	histogram.Record(ctx, 0.123) // Record a request that took 0.123 seconds.
+	recordRequestDuration(ctx, tracer, histogram, 0.023) // Record a request that took 0.023 seconds.
+	recordRequestDuration(ctx, tracer, histogram, 1.632) // Record a request that took 1.632 seconds.
+	recordRequestDuration(ctx, tracer, histogram, 0.345) // Record a request that took 0.345 seconds.
+	recordRequestDuration(ctx, tracer, histogram, 0.123) // Record a request that took 0.123 seconds.
+
+	// Another histogram, recording request body sizes in bytes and aggregated
+	// exponentially (see defaultViewRules) rather than into explicit buckets like
+	// demo.request.duration above, so the two histogram flavors can be compared side
+	// by side in Prometheus.
+	sizeHistogram, err := meter.Int64Histogram(
+		"demo.request.size",
+		metric.WithDescription("The distribution of demo request body sizes."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create Histogram: %v", err)
+	}
+	sizeHistogram.Record(ctx, 128)
+	sizeHistogram.Record(ctx, 4096)
+	sizeHistogram.Record(ctx, 512)
+	sizeHistogram.Record(ctx, 65536)
 
 	// Asynchronous Gauge.
 	_, err = meter.Int64ObservableGauge(
@@ -138,3 +181,13 @@ func createAndRecordMetrics(ctx context.Context, meter metric.Meter) {
 	partitionedCounter.Add(ctx, 33, metric.WithAttributes(attribute.String("demo.method", "GET"), attribute.String("demo.path", "/users")))
 	partitionedCounter.Add(ctx, 97, metric.WithAttributes(attribute.String("demo.method", "POST"), attribute.String("demo.path", "/users")))
 }
+
+// recordRequestDuration wraps a single histogram observation in its own span, so that if
+// the span is sampled the resulting data point is exported with a trace-based exemplar
+// pointing back at it.
+func recordRequestDuration(ctx context.Context, tracer trace.Tracer, histogram metric.Float64Histogram, seconds float64) {
+	spanCtx, span := tracer.Start(ctx, "demo.handle-request")
+	defer span.End()
+
+	histogram.Record(spanCtx, seconds)
+}
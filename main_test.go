@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/promlabs/otel-instrumentation-example/internal/metricstest"
+)
+
+func TestCreateAndRecordMetrics(t *testing.T) {
+	h := metricstest.New(t)
+	tracer := noop.NewTracerProvider().Tracer("test")
+
+	createAndRecordMetrics(context.Background(), h.Meter("test"), tracer)
+
+	if got := h.MustGetCounter("demo.handled_items"); got != 24 {
+		t.Errorf("demo.handled_items = %d, want 24", got)
+	}
+
+	hist := h.MustGetHistogramSnapshot("demo.request.duration")
+	const wantSum = 0.023 + 1.632 + 0.345 + 0.123
+	if diff := hist.Sum - wantSum; diff < -1e-9 || diff > 1e-9 {
+		t.Errorf("demo.request.duration sum = %v, want %v", hist.Sum, wantSum)
+	}
+	if hist.Count != 4 {
+		t.Errorf("demo.request.duration count = %d, want 4", hist.Count)
+	}
+	wantBucketCounts := []uint64{1, 0, 1, 1, 0, 1, 0, 0}
+	if len(hist.BucketCounts) != len(wantBucketCounts) {
+		t.Fatalf("demo.request.duration has %d buckets, want %d", len(hist.BucketCounts), len(wantBucketCounts))
+	}
+	for i, want := range wantBucketCounts {
+		if hist.BucketCounts[i] != want {
+			t.Errorf("demo.request.duration bucket %d = %d, want %d", i, hist.BucketCounts[i], want)
+		}
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		want         int64
+	}{
+		{"GET", "/items", 58},
+		{"POST", "/items", 81},
+		{"GET", "/users", 33},
+		{"POST", "/users", 97},
+	} {
+		got := h.MustGetCounter("demo.request.count",
+			attribute.String("demo.method", tc.method), attribute.String("demo.path", tc.path))
+		if got != tc.want {
+			t.Errorf("demo.request.count{method=%s,path=%s} = %d, want %d", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	sdk_metric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// aggregationOverride names an alternative aggregation a view can swap in for an
+// instrument's default one.
+type aggregationOverride string
+
+const (
+	aggregationExponentialHistogram aggregationOverride = "exponential_histogram"
+)
+
+// viewRule describes one entry of the form
+// {"instrument": "demo.request.size", "aggregation": "exponential_histogram", "max_size": 160, "max_scale": 20}
+// that buildMeterProvider (config.go) uses to build the MeterProvider's Views.
+type viewRule struct {
+	instrumentNamePattern string
+	aggregation           aggregationOverride
+	maxSize               int32
+	maxScale              int32
+}
+
+// defaultViewRules swaps the explicit-bucket histogram aggregation for an
+// exponential/base-2 one on demo.request.size, so the example exposes both
+// aggregations side by side for comparison in Prometheus.
+var defaultViewRules = []viewRule{
+	{
+		instrumentNamePattern: "demo.request.size",
+		aggregation:           aggregationExponentialHistogram,
+		maxSize:               160,
+		maxScale:              20,
+	},
+}
+
+// buildViews turns viewRules into the sdk_metric.View options buildMeterProvider passes
+// to sdk_metric.WithView.
+func buildViews(rules []viewRule) []sdk_metric.View {
+	views := make([]sdk_metric.View, 0, len(rules))
+	for _, rule := range rules {
+		rule := rule
+		var stream sdk_metric.Stream
+		switch rule.aggregation {
+		case aggregationExponentialHistogram:
+			stream.Aggregation = sdk_metric.AggregationBase2ExponentialHistogram{
+				MaxSize:  rule.maxSize,
+				MaxScale: rule.maxScale,
+			}
+		}
+		views = append(views, sdk_metric.NewView(
+			sdk_metric.Instrument{Name: rule.instrumentNamePattern},
+			stream,
+		))
+	}
+	return views
+}
+
+// temporalityMode selects which sdk_metric.TemporalitySelector buildTemporalitySelector
+// returns. It is driven by the OTEL_METRICS_TEMPORALITY env var (see config.go) and
+// defaults to cumulative, matching Prometheus's OTLP receiver, which only accumulates
+// cumulative points out of the box.
+type temporalityMode string
+
+const (
+	temporalityCumulative temporalityMode = "cumulative"
+	temporalityDelta      temporalityMode = "delta"
+)
+
+// deltaTemporalityInstrumentKinds lists the instrument kinds reported with delta rather
+// than cumulative temporality when mode is temporalityDelta, matching the
+// DeltaTemporalitySelector pattern used by other OTel Go exporters (e.g. Heroku's and
+// rudder's) for exporting to backends that prefer to receive deltas.
+var deltaTemporalityInstrumentKinds = map[sdk_metric.InstrumentKind]bool{
+	sdk_metric.InstrumentKindCounter:   true,
+	sdk_metric.InstrumentKindHistogram: true,
+}
+
+// buildTemporalitySelector returns the sdk_metric.TemporalitySelector the OTLP exporters
+// are configured with, per mode. Any mode other than temporalityDelta yields the SDK's
+// default (cumulative) selector; parseConfig rejects unrecognized OTEL_METRICS_TEMPORALITY
+// values before this is called.
+func buildTemporalitySelector(mode temporalityMode) sdk_metric.TemporalitySelector {
+	if mode != temporalityDelta {
+		return sdk_metric.DefaultTemporalitySelector
+	}
+	return func(kind sdk_metric.InstrumentKind) metricdata.Temporality {
+		if deltaTemporalityInstrumentKinds[kind] {
+			return metricdata.DeltaTemporality
+		}
+		return sdk_metric.DefaultTemporalitySelector(kind)
+	}
+}